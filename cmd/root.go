@@ -0,0 +1,87 @@
+// Package cmd implements the tfvar command-line interface.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shihanng/tfvar/pkg/tfvar"
+	"github.com/shihanng/tfvar/pkg/tfvar/cliparse"
+)
+
+var (
+	varArgs  []string
+	varFiles []string
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "tfvar [dir]",
+	Short: "Extract and resolve Terraform input variables",
+	Long: `tfvar extracts the input variables declared in a Terraform configuration and
+resolves their effective values the way "terraform plan" would, then prints
+them in Terraform's variable definitions format.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRoot,
+}
+
+// Execute runs the tfvar root command, exiting the process with a non-zero
+// status on error.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringArrayVar(&varArgs, "var", nil, `Set a variable, e.g. --var 'foo=bar' or --var 'tags={env="dev"}'. Can be repeated.`)
+	rootCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Read variable values from a .tfvars or .tfvars.json file. Can be repeated.")
+}
+
+func runRoot(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	decl, err := tfvar.Load(dir)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]tfvar.Variable, len(decl))
+	for _, v := range decl {
+		byName[v.Name] = v
+	}
+
+	// Parse --var-file and --var through cliparse once, up front, so a
+	// malformed one is reported with a precise diagnostic before anything
+	// is merged. The results are fed into LoadValues as already-parsed
+	// values rather than handing it the raw strings to parse a second time.
+	opts := make([]tfvar.LoadOption, 0, len(varFiles)+len(varArgs))
+
+	for _, path := range varFiles {
+		values, err := cliparse.ParseVarFile(path, byName)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, tfvar.WithVarFileValues(path, values))
+	}
+
+	for _, raw := range varArgs {
+		name, value, err := cliparse.ParseVarArg(raw, byName)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, tfvar.WithVarValue(name, value))
+	}
+
+	vars, err := tfvar.LoadValues(dir, opts...)
+	if err != nil {
+		return err
+	}
+
+	return tfvar.WriteAsTFVars(cmd.OutOrStdout(), vars, "", false, false)
+}
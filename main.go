@@ -0,0 +1,7 @@
+package main
+
+import "github.com/shihanng/tfvar/cmd"
+
+func main() {
+	cmd.Execute()
+}
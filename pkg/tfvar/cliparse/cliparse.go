@@ -0,0 +1,130 @@
+// Package cliparse parses the raw strings a command-line user supplies for
+// "-var" and "-var-file" flags into cty.Value, deferring to
+// tfvar.Variable.ParseCLIValue for the literal-vs-HCL decision so a caller
+// can feed the parsed result straight into tfvar.LoadValues via
+// tfvar.WithVarValue/WithVarFileValues instead of handing LoadValues the
+// raw string to parse all over again.
+package cliparse
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/shihanng/tfvar/pkg/tfvar"
+)
+
+// ParseVarArg splits a "-var" argument of the form "name=value" and parses
+// value for the declared variable decl[name], using tfvar.Variable's own
+// ParseCLIValue so this makes exactly the same literal-vs-HCL decision
+// LoadValues makes for a raw argument given with tfvar.WithVar -- the two
+// can't silently disagree about how a given -var was parsed.
+//
+// A variable with no entry in decl has no parsing mode to consult; value is
+// then parsed as an HCL expression with hclsyntax.ParseExpression and used
+// as-is, falling back to a literal string if it isn't valid HCL or doesn't
+// evaluate (e.g. because it looks like a reference to an undefined
+// variable). Diagnostics from a failed parse or conversion carry the source
+// position of value within the synthesized "-var" expression.
+func ParseVarArg(raw string, decl map[string]tfvar.Variable) (string, cty.Value, error) {
+	name, rawVal, err := splitNameValue(raw)
+	if err != nil {
+		return "", cty.NilVal, err
+	}
+
+	v, ok := decl[name]
+	if ok {
+		val, err := v.ParseCLIValue(rawVal)
+		if err != nil {
+			return "", cty.NilVal, errors.Wrapf(err, "tfvar: -var %s", name)
+		}
+		return name, val, nil
+	}
+
+	filename := "<value for -var " + name + ">"
+
+	expr, diags := hclsyntax.ParseExpression([]byte(rawVal), filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		// Not valid HCL; the most useful thing left to do is take it
+		// literally.
+		return name, cty.StringVal(rawVal), nil
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		// Syntactically valid but doesn't evaluate without an EvalContext
+		// (e.g. a bare word that looks like a variable reference); again,
+		// fall back to taking it literally.
+		return name, cty.StringVal(rawVal), nil
+	}
+
+	return name, val, nil
+}
+
+// ParseVarFile reads a "-var-file" argument: an HCL-syntax ".tfvars" file
+// parsed with hclparse, or a ".tfvars.json" file parsed as JSON. Each
+// attribute's value is converted to the declared type of the matching
+// entry in decl, when there is one.
+func ParseVarFile(path string, decl map[string]tfvar.Variable) (map[string]cty.Value, error) {
+	parser := hclparse.NewParser()
+
+	var (
+		f    *hcl.File
+		diag hcl.Diagnostics
+	)
+	if strings.HasSuffix(path, ".json") {
+		f, diag = parser.ParseJSONFile(path)
+	} else {
+		f, diag = parser.ParseHCLFile(path)
+	}
+	if diag.HasErrors() {
+		return nil, errors.Wrapf(diag, "tfvar: parsing %s", path)
+	}
+
+	attrs, diag := f.Body.JustAttributes()
+	if diag.HasErrors() {
+		return nil, errors.Wrapf(diag, "tfvar: reading attributes from %s", path)
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+
+	for name, attr := range attrs {
+		val, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() {
+			return nil, errors.Wrapf(diag, "tfvar: evaluating %s in %s", name, path)
+		}
+
+		converted, err := convert.Convert(val, declaredType(decl, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: %s in %s does not match declared type", name, path)
+		}
+
+		values[name] = converted
+	}
+
+	return values, nil
+}
+
+// declaredType returns decl[name]'s declared type, or cty.DynamicPseudoType
+// if name isn't declared or has no type of its own.
+func declaredType(decl map[string]tfvar.Variable, name string) cty.Type {
+	v, ok := decl[name]
+	if !ok || v.Type == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return v.Type
+}
+
+// splitNameValue splits raw of the form "name=value" into its two parts.
+func splitNameValue(raw string) (name, value string, err error) {
+	eq := strings.IndexByte(raw, '=')
+	if eq == -1 {
+		return "", "", errors.Newf("tfvar: invalid -var %q, expected NAME=VALUE", raw)
+	}
+	return raw[:eq], raw[eq+1:], nil
+}
@@ -0,0 +1,125 @@
+package cliparse
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/shihanng/tfvar/pkg/tfvar"
+)
+
+func loadDecl(t *testing.T, src string) map[string]tfvar.Variable {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(src), 0o644))
+
+	vars, err := tfvar.Load(dir)
+	require.NoError(t, err)
+
+	decl := make(map[string]tfvar.Variable, len(vars))
+	for _, v := range vars {
+		decl[v.Name] = v
+	}
+	return decl
+}
+
+func TestParseVarArg(t *testing.T) {
+	decl := loadDecl(t, `
+variable "region" {
+  type = string
+}
+
+variable "tags" {
+  type = map(string)
+}
+`)
+
+	tests := []struct {
+		name      string
+		raw       string
+		wantName  string
+		wantValue cty.Value
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "declared string takes the value literally",
+			raw:       `region=us-west-1`,
+			wantName:  "region",
+			wantValue: cty.StringVal("us-west-1"),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "declared map parses as HCL",
+			raw:       `tags={env="dev"}`,
+			wantName:  "tags",
+			wantValue: cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev")}),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "undeclared variable falls back to literal when the HCL does not evaluate",
+			raw:       `name=us-west-1`,
+			wantName:  "name",
+			wantValue: cty.StringVal("us-west-1"),
+			assertion: assert.NoError,
+		},
+		{
+			name:      "missing equals sign is an error",
+			raw:       `region`,
+			assertion: assert.Error,
+		},
+		{
+			name:      "value that doesn't match the declared type is an error",
+			raw:       `tags=us-west-1`,
+			assertion: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, value, err := ParseVarArg(tt.raw, decl)
+			tt.assertion(t, err)
+			if err == nil {
+				assert.Equal(t, tt.wantName, name)
+				assert.True(t, tt.wantValue.RawEquals(value), "got %#v, want %#v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParseVarFile(t *testing.T) {
+	decl := loadDecl(t, `
+variable "region" {
+  type = string
+}
+`)
+
+	t.Run("hcl syntax", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "a.tfvars")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`region = "us-west-1"`), 0o644))
+
+		values, err := ParseVarFile(path, decl)
+		require.NoError(t, err)
+		assert.True(t, cty.StringVal("us-west-1").RawEquals(values["region"]))
+	})
+
+	t.Run("json syntax", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "a.tfvars.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"region": "us-west-1"}`), 0o644))
+
+		values, err := ParseVarFile(path, decl)
+		require.NoError(t, err)
+		assert.True(t, cty.StringVal("us-west-1").RawEquals(values["region"]))
+	})
+
+	t.Run("missing file is an error", func(t *testing.T) {
+		_, err := ParseVarFile(filepath.Join(t.TempDir(), "missing.tfvars"), decl)
+		assert.Error(t, err)
+	})
+}
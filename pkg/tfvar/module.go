@@ -0,0 +1,214 @@
+package tfvar
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/terraform/configs"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ModuleResolver resolves a `module` block's "source" address to a local
+// directory that LoadRecursive can read configuration from. It is only
+// consulted for sources LoadRecursive doesn't already know how to follow
+// itself, i.e. anything other than a local path ("./..." or "../...").
+type ModuleResolver interface {
+	Resolve(sourceAddr string) (dir string, err error)
+}
+
+// LoadRecursiveOption configures LoadRecursive.
+type LoadRecursiveOption func(*loadRecursiveOptions)
+
+type loadRecursiveOptions struct {
+	resolver ModuleResolver
+}
+
+// WithModuleResolver supplies a ModuleResolver for LoadRecursive to consult
+// when it encounters a module "source" that isn't a local path. Without
+// one, such module calls are left unfollowed.
+func WithModuleResolver(r ModuleResolver) LoadRecursiveOption {
+	return func(o *loadRecursiveOptions) {
+		o.resolver = r
+	}
+}
+
+// LoadRecursive behaves like Load but also descends into every `module`
+// block it finds, flattening the child modules' variables into the
+// returned list. Each variable's Name is prefixed with its module call
+// path (e.g. "network.subnet_cidr" for a subnet_cidr variable declared
+// inside `module "network" { ... }`), and its ModulePath records that path
+// as a slice so callers can group or re-namespace them.
+//
+// Where the calling module already passes a value for a child variable
+// (an attribute in its `module` block body), that value is evaluated and
+// surfaced as the child variable's Value, so recursive loading also shows
+// which child inputs are still left unset.
+//
+// Local path module sources are always followed; anything else is resolved
+// via a ModuleResolver supplied with WithModuleResolver, or skipped if none
+// is given.
+func LoadRecursive(dir string, opts ...LoadRecursiveOption) ([]Variable, error) {
+	o := &loadRecursiveOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tfvar: resolving %s", dir)
+	}
+
+	return loadRecursive(dir, nil, o, map[string]bool{absDir: true})
+}
+
+// loadRecursive descends into dir's module calls, refusing to follow one
+// whose resolved directory is already in visited: that means a module
+// (anywhere up the current call chain, e.g. via a local-path source that
+// loops back on an ancestor) sources itself, which would otherwise recurse
+// forever. visited is keyed by absolute path and scoped to the current
+// ancestor chain, not the whole call tree, so the same child module reached
+// by two different module calls (a diamond, not a cycle) is still fine.
+func loadRecursive(dir string, modulePath []string, o *loadRecursiveOptions, visited map[string]bool) ([]Variable, error) {
+	vars, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range vars {
+		vars[i].ModulePath = append([]string(nil), modulePath...)
+	}
+
+	parser, _, err := newConfigParser(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, diag := parser.LoadConfigDir(dir)
+	if diag.HasErrors() {
+		return nil, errors.Wrap(diag, "tfvar: loading config")
+	}
+
+	names := make([]string, 0, len(mod.ModuleCalls))
+	for name := range mod.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mc := mod.ModuleCalls[name]
+
+		childDir, err := resolveModuleSource(dir, mc.SourceAddr, o.resolver)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: resolving module %q", name)
+		}
+		if childDir == "" {
+			// No local path and no resolver (or the resolver declined); we
+			// can't see inside this module call.
+			continue
+		}
+
+		absChildDir, err := filepath.Abs(childDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: resolving module %q", name)
+		}
+		if visited[absChildDir] {
+			return nil, errors.Newf("tfvar: module %q at %s forms a cycle", name, childDir)
+		}
+
+		childVisited := make(map[string]bool, len(visited)+1)
+		for k := range visited {
+			childVisited[k] = true
+		}
+		childVisited[absChildDir] = true
+
+		childPath := append(append([]string(nil), modulePath...), name)
+
+		childVars, err := loadRecursive(childDir, childPath, o, childVisited)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: module %q", name)
+		}
+
+		args, err := valuesFromModuleCall(mc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: reading arguments passed to module %q", name)
+		}
+
+		for i, cv := range childVars {
+			val, ok := args[cv.Name]
+			if !ok {
+				continue
+			}
+
+			converted, err := convert.Convert(val, cv.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "tfvar: argument %q passed to module %q", cv.Name, name)
+			}
+
+			childVars[i].Value = converted
+		}
+
+		for i := range childVars {
+			childVars[i].Name = name + "." + childVars[i].Name
+		}
+
+		vars = append(vars, childVars...)
+	}
+
+	return vars, nil
+}
+
+// LeafName returns v.Name with its ModulePath prefix stripped, e.g.
+// "subnet_cidr" for a variable with Name "network.subnet_cidr" and
+// ModulePath []string{"network"}. It is equal to Name for variables with no
+// ModulePath. WriteAsEnvVars and WriteAsTFVars use this so that a variable
+// keeps the same name a wrapping root module would declare it under,
+// regardless of how deep LoadRecursive found it.
+func (v Variable) LeafName() string {
+	if len(v.ModulePath) == 0 {
+		return v.Name
+	}
+
+	prefix := strings.Join(v.ModulePath, ".") + "."
+	return strings.TrimPrefix(v.Name, prefix)
+}
+
+// resolveModuleSource turns a module call's source address into a local
+// directory. Local paths are joined onto parentDir directly; anything else
+// is delegated to resolver, if one is given. It returns "" with a nil error
+// when the source can't be followed.
+func resolveModuleSource(parentDir, sourceAddr string, resolver ModuleResolver) (string, error) {
+	if strings.HasPrefix(sourceAddr, "./") || strings.HasPrefix(sourceAddr, "../") {
+		return filepath.Join(parentDir, sourceAddr), nil
+	}
+
+	if resolver == nil {
+		return "", nil
+	}
+
+	return resolver.Resolve(sourceAddr)
+}
+
+// valuesFromModuleCall evaluates the attributes a `module` block body
+// passes to its child, skipping any whose expression can't be evaluated
+// without a full Terraform graph (e.g. a reference to a resource or local
+// value): those are left for the child's own default, if it has one.
+func valuesFromModuleCall(mc *configs.ModuleCall) (map[string]cty.Value, error) {
+	attrs, diag := mc.Config.JustAttributes()
+	if diag.HasErrors() {
+		return nil, errors.Wrap(diag, "tfvar: reading module call arguments")
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() {
+			continue
+		}
+		values[name] = val
+	}
+
+	return values, nil
+}
@@ -0,0 +1,108 @@
+package tfvar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestLoadRecursive(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "network")
+	require.NoError(t, os.Mkdir(child, 0o755))
+
+	writeFile(t, root, "main.tf", `
+variable "root_only" {
+}
+
+module "network" {
+  source     = "./network"
+  subnet_cidr = "10.0.1.0/24"
+}
+`)
+	writeFile(t, child, "main.tf", `
+variable "subnet_cidr" {
+  type = string
+}
+
+variable "subnet_name" {
+  type    = string
+  default = "default-subnet"
+}
+`)
+
+	vars, err := LoadRecursive(root)
+	require.NoError(t, err)
+
+	byName := make(map[string]Variable, len(vars))
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+
+	require.Contains(t, byName, "root_only")
+	assert.Empty(t, byName["root_only"].ModulePath)
+
+	require.Contains(t, byName, "network.subnet_cidr")
+	assert.Equal(t, []string{"network"}, byName["network.subnet_cidr"].ModulePath)
+	assert.Equal(t, "subnet_cidr", byName["network.subnet_cidr"].LeafName())
+	assert.True(t, cty.StringVal("10.0.1.0/24").RawEquals(byName["network.subnet_cidr"].Value))
+
+	require.Contains(t, byName, "network.subnet_name")
+	assert.True(t, cty.StringVal("default-subnet").RawEquals(byName["network.subnet_name"].Value))
+}
+
+func TestLoadRecursiveDetectsCycles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.tf", `
+module "self" {
+  source = "./"
+}
+`)
+
+	_, err := LoadRecursive(root)
+	assert.Error(t, err)
+}
+
+type fakeResolver struct {
+	dir string
+}
+
+func (r fakeResolver) Resolve(sourceAddr string) (string, error) {
+	return r.dir, nil
+}
+
+func TestLoadRecursiveUsesModuleResolver(t *testing.T) {
+	root := t.TempDir()
+	remote := t.TempDir()
+
+	writeFile(t, root, "main.tf", `
+module "registry_module" {
+  source = "example/module/registry"
+}
+`)
+	writeFile(t, remote, "main.tf", `
+variable "from_registry" {
+}
+`)
+
+	vars, err := LoadRecursive(root, WithModuleResolver(fakeResolver{dir: remote}))
+	require.NoError(t, err)
+
+	var found bool
+	for _, v := range vars {
+		if v.Name == "registry_module.from_registry" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,151 @@
+package tfvar
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform/configs"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// overlaySource caches, by the exact path string handed to configs.Parser,
+// the stripped bytes stripSensitiveAttributes actually staged into the
+// overlay filesystem. The real parser's diagnostics and expression ranges
+// (e.g. a Validation.Condition's hcl.Expression) are all relative to those
+// stripped bytes, not the on-disk original, since removing a "sensitive"
+// attribute shifts every later byte offset in the file; conditionSource
+// reads this cache instead of the original file to keep its slicing in
+// sync with those ranges.
+var overlaySource sync.Map
+
+// newConfigParser returns a configs.Parser for dir, along with a map from
+// variable name to its `sensitive = true` attribute read directly from the
+// native-syntax ".tf" files in dir.
+//
+// The pinned github.com/hashicorp/terraform@v0.12.27 this package parses
+// configuration with predates the `sensitive` attribute on a variable block
+// entirely, so its schema rejects it outright with an "Unsupported
+// argument" error. newConfigParser works around that by reading dir's
+// files itself first, stripping any "sensitive" attribute out of each
+// `variable` block, and handing the result to configs.NewParser through an
+// in-memory overlay filesystem so the real parser never sees an attribute
+// it doesn't understand.
+func newConfigParser(dir string) (*configs.Parser, map[string]bool, error) {
+	overlay, sensitive, err := stripSensitiveAttributes(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return configs.NewParser(overlay), sensitive, nil
+}
+
+// stripSensitiveAttributes copies every ".tf" file in dir into an in-memory
+// filesystem with each `variable` block's "sensitive" attribute, if any,
+// removed, recording its value in the returned map keyed by variable name.
+// Other files (".tf.json", overrides, etc.) are left for configs.Parser to
+// read from dir directly, since the overlay only intercepts what the caller
+// asked it to serve.
+func stripSensitiveAttributes(dir string) (afero.Fs, map[string]bool, error) {
+	osFS := afero.NewOsFs()
+
+	infos, err := afero.ReadDir(osFS, dir)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "tfvar: reading %s", dir)
+	}
+
+	overlay := afero.NewCopyOnWriteFs(osFS, afero.NewMemMapFs())
+	sensitive := make(map[string]bool)
+
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".tf") {
+			continue
+		}
+
+		path := filepath.Join(dir, info.Name())
+
+		src, err := afero.ReadFile(osFS, path)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "tfvar: reading %s", path)
+		}
+
+		stripped := stripSensitiveFromFile(path, src, sensitive)
+		overlaySource.Store(path, stripped)
+
+		if err := afero.WriteFile(overlay, path, stripped, 0o644); err != nil {
+			return nil, nil, errors.Wrapf(err, "tfvar: staging %s", path)
+		}
+	}
+
+	return overlay, sensitive, nil
+}
+
+// stripSensitiveFromFile records the `sensitive` attribute of every
+// `variable` block in src into sensitive, keyed by the variable's name, and
+// returns src with those attributes removed. Any file that doesn't parse as
+// HCL is returned unchanged; configs.Parser will produce the real
+// diagnostic for it.
+func stripSensitiveFromFile(path string, src []byte, sensitive map[string]bool) []byte {
+	read, diag := hclparse.NewParser().ParseHCL(src, path)
+	if diag.HasErrors() {
+		return src
+	}
+
+	body, ok := read.Body.(*hclsyntax.Body)
+	if !ok {
+		return src
+	}
+
+	var found bool
+	for _, block := range body.Blocks {
+		if block.Type != "variable" || len(block.Labels) == 0 {
+			continue
+		}
+
+		attr, ok := block.Body.Attributes["sensitive"]
+		if !ok {
+			continue
+		}
+
+		val, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() {
+			continue
+		}
+
+		bv, err := convert.Convert(val, cty.Bool)
+		if err != nil {
+			continue
+		}
+
+		sensitive[block.Labels[0]] = bv.True()
+		found = true
+	}
+
+	if !found {
+		return src
+	}
+
+	write, diag := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+	if diag.HasErrors() {
+		return src
+	}
+
+	for _, block := range write.Body().Blocks() {
+		if block.Type() != "variable" || len(block.Labels()) == 0 {
+			continue
+		}
+		if _, ok := sensitive[block.Labels()[0]]; !ok {
+			continue
+		}
+		block.Body().RemoveAttribute("sensitive")
+	}
+
+	return write.Bytes()
+}
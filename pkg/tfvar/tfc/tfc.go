@@ -0,0 +1,197 @@
+// Package tfc pushes a tfvar.Variable set into a Terraform Cloud/Enterprise
+// workspace as workspace variables, so a module's declared inputs can be
+// reconciled against the workspace that runs it.
+package tfc
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/shihanng/tfvar/pkg/tfvar"
+)
+
+// Action records what Push did, or would do under PushOptions.DryRun, to a
+// single workspace variable.
+type Action string
+
+// The possible outcomes for a pushed variable.
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionSkipped Action = "skipped"
+	ActionDeleted Action = "deleted"
+)
+
+// Change describes one variable's outcome from a Push call.
+type Change struct {
+	Key    string
+	Action Action
+}
+
+// Report summarizes everything a Push call did, or would do under DryRun,
+// so callers such as a CI pipeline can log the diff.
+type Report struct {
+	Changes []Change
+}
+
+// PushOptions controls how Push reconciles a variable set with a workspace.
+type PushOptions struct {
+	// DryRun reports what Push would do without making any API calls that
+	// change the workspace.
+	DryRun bool
+
+	// DeleteExtraneous removes workspace variables that have no
+	// corresponding entry in the pushed variable set.
+	DeleteExtraneous bool
+
+	// Category overrides the category every newly pushed variable is
+	// created under. It defaults to tfe.CategoryTerraform; set it to
+	// tfe.CategoryEnv to push as environment variables instead. An existing
+	// workspace variable keeps its category when it is updated.
+	Category tfe.CategoryType
+}
+
+// Push reconciles vars into the Terraform Cloud/Enterprise workspace
+// identified by workspaceID. A workspace variable is created for any
+// Variable with no existing counterpart, updated in place when its value,
+// description, or sensitivity has changed, and left untouched otherwise.
+// Values of a non-primitive declared type (list, map, object, ...) are sent
+// with HCL set, serialized via hclwrite.TokensForValue, since Terraform
+// Cloud expects such values as HCL rather than plain strings. With
+// opts.DeleteExtraneous, any workspace variable absent from vars is
+// removed. With opts.DryRun, no create/update/delete calls are made, but
+// the returned Report still reflects what would have happened.
+func Push(ctx context.Context, client *tfe.Client, workspaceID string, vars []tfvar.Variable, opts PushOptions) (Report, error) {
+	category := opts.Category
+	if category == "" {
+		category = tfe.CategoryTerraform
+	}
+
+	existing, err := listVariables(ctx, client, workspaceID)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "tfc: listing workspace variables")
+	}
+
+	var report Report
+
+	seen := make(map[string]bool, len(vars))
+
+	for _, v := range vars {
+		seen[v.Name] = true
+
+		value, isHCL := encodeValue(v)
+
+		cur, ok := existing[v.Name]
+		if !ok {
+			if !opts.DryRun {
+				_, err := client.Variables.Create(ctx, workspaceID, tfe.VariableCreateOptions{
+					Key:         tfe.String(v.Name),
+					Value:       tfe.String(value),
+					Description: tfe.String(v.Description),
+					Category:    tfe.Category(category),
+					HCL:         tfe.Bool(isHCL),
+					Sensitive:   tfe.Bool(v.Sensitive),
+				})
+				if err != nil {
+					return report, errors.Wrapf(err, "tfc: creating %q", v.Name)
+				}
+			}
+
+			report.Changes = append(report.Changes, Change{Key: v.Name, Action: ActionCreated})
+			continue
+		}
+
+		if cur.Value == value && cur.HCL == isHCL && cur.Description == v.Description && cur.Sensitive == v.Sensitive {
+			report.Changes = append(report.Changes, Change{Key: v.Name, Action: ActionSkipped})
+			continue
+		}
+
+		if !opts.DryRun {
+			_, err := client.Variables.Update(ctx, workspaceID, cur.ID, tfe.VariableUpdateOptions{
+				Value:       tfe.String(value),
+				Description: tfe.String(v.Description),
+				HCL:         tfe.Bool(isHCL),
+				Sensitive:   tfe.Bool(v.Sensitive),
+			})
+			if err != nil {
+				return report, errors.Wrapf(err, "tfc: updating %q", v.Name)
+			}
+		}
+
+		report.Changes = append(report.Changes, Change{Key: v.Name, Action: ActionUpdated})
+	}
+
+	if opts.DeleteExtraneous {
+		for name, cur := range existing {
+			if seen[name] {
+				continue
+			}
+
+			if !opts.DryRun {
+				if err := client.Variables.Delete(ctx, workspaceID, cur.ID); err != nil {
+					return report, errors.Wrapf(err, "tfc: deleting %q", name)
+				}
+			}
+
+			report.Changes = append(report.Changes, Change{Key: name, Action: ActionDeleted})
+		}
+	}
+
+	return report, nil
+}
+
+// listVariables reads every variable already defined on the workspace,
+// following pagination, keyed by its name.
+func listVariables(ctx context.Context, client *tfe.Client, workspaceID string) (map[string]*tfe.Variable, error) {
+	existing := make(map[string]*tfe.Variable)
+
+	opts := tfe.VariableListOptions{}
+	for {
+		page, err := client.Variables.List(ctx, workspaceID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range page.Items {
+			existing[v.Key] = v
+		}
+
+		if page.NextPage == 0 {
+			break
+		}
+		opts.PageNumber = page.NextPage
+	}
+
+	return existing, nil
+}
+
+// encodeValue renders v's value for a TFE workspace variable. Primitive
+// values (string, number, bool) are unquoted so they go through as plain
+// TFE variables; anything else is kept as its HCL literal, to be sent with
+// HCL set.
+func encodeValue(v tfvar.Variable) (value string, isHCL bool) {
+	t := v.Type
+	if t == cty.NilType {
+		t = v.Value.Type()
+	}
+
+	val := v.Value
+	if val == cty.NilVal {
+		val = cty.NullVal(t)
+	}
+
+	b := bytes.TrimSpace(hclwrite.TokensForValue(val).Bytes())
+
+	if t.IsPrimitiveType() {
+		b = bytes.TrimPrefix(b, []byte(`"`))
+		b = bytes.TrimSuffix(b, []byte(`"`))
+		return string(b), false
+	}
+
+	return string(b), true
+}
@@ -0,0 +1,199 @@
+package tfc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/shihanng/tfvar/pkg/tfvar"
+)
+
+// fakeVariables is an in-memory tfe.Variables that lets Push be tested
+// without a real Terraform Cloud workspace.
+type fakeVariables struct {
+	byID  map[string]*tfe.Variable
+	calls []string
+}
+
+func newFakeVariables(existing ...*tfe.Variable) *fakeVariables {
+	f := &fakeVariables{byID: make(map[string]*tfe.Variable)}
+	for _, v := range existing {
+		f.byID[v.ID] = v
+	}
+	return f
+}
+
+func (f *fakeVariables) List(ctx context.Context, workspaceID string, options tfe.VariableListOptions) (*tfe.VariableList, error) {
+	items := make([]*tfe.Variable, 0, len(f.byID))
+	for _, v := range f.byID {
+		items = append(items, v)
+	}
+	return &tfe.VariableList{Pagination: &tfe.Pagination{}, Items: items}, nil
+}
+
+func (f *fakeVariables) Create(ctx context.Context, workspaceID string, options tfe.VariableCreateOptions) (*tfe.Variable, error) {
+	f.calls = append(f.calls, "create:"+*options.Key)
+
+	v := &tfe.Variable{
+		ID:        "new-" + *options.Key,
+		Key:       *options.Key,
+		Value:     *options.Value,
+		Category:  *options.Category,
+		HCL:       *options.HCL,
+		Sensitive: *options.Sensitive,
+	}
+	if options.Description != nil {
+		v.Description = *options.Description
+	}
+	f.byID[v.ID] = v
+	return v, nil
+}
+
+func (f *fakeVariables) Read(ctx context.Context, workspaceID string, variableID string) (*tfe.Variable, error) {
+	return f.byID[variableID], nil
+}
+
+func (f *fakeVariables) Update(ctx context.Context, workspaceID string, variableID string, options tfe.VariableUpdateOptions) (*tfe.Variable, error) {
+	f.calls = append(f.calls, "update:"+variableID)
+
+	v := f.byID[variableID]
+	if options.Value != nil {
+		v.Value = *options.Value
+	}
+	if options.Description != nil {
+		v.Description = *options.Description
+	}
+	if options.HCL != nil {
+		v.HCL = *options.HCL
+	}
+	if options.Sensitive != nil {
+		v.Sensitive = *options.Sensitive
+	}
+	return v, nil
+}
+
+func (f *fakeVariables) Delete(ctx context.Context, workspaceID string, variableID string) error {
+	f.calls = append(f.calls, "delete:"+variableID)
+	delete(f.byID, variableID)
+	return nil
+}
+
+func TestPush(t *testing.T) {
+	t.Run("creates a variable with no existing counterpart", func(t *testing.T) {
+		fake := newFakeVariables()
+		client := &tfe.Client{Variables: fake}
+
+		vars := []tfvar.Variable{
+			{Name: "region", Type: cty.String, Value: cty.StringVal("us-west-1")},
+		}
+
+		report, err := Push(context.Background(), client, "ws-1", vars, PushOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Change{{Key: "region", Action: ActionCreated}}, report.Changes)
+		assert.Equal(t, []string{"create:region"}, fake.calls)
+	})
+
+	t.Run("updates a variable whose value changed", func(t *testing.T) {
+		fake := newFakeVariables(&tfe.Variable{
+			ID: "var-1", Key: "region", Value: "us-east-1", Category: tfe.CategoryTerraform,
+		})
+		client := &tfe.Client{Variables: fake}
+
+		vars := []tfvar.Variable{
+			{Name: "region", Type: cty.String, Value: cty.StringVal("us-west-1")},
+		}
+
+		report, err := Push(context.Background(), client, "ws-1", vars, PushOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Change{{Key: "region", Action: ActionUpdated}}, report.Changes)
+		assert.Equal(t, []string{"update:var-1"}, fake.calls)
+		assert.Equal(t, "us-west-1", fake.byID["var-1"].Value)
+	})
+
+	t.Run("skips a variable that already matches", func(t *testing.T) {
+		fake := newFakeVariables(&tfe.Variable{
+			ID: "var-1", Key: "region", Value: "us-west-1", Category: tfe.CategoryTerraform,
+		})
+		client := &tfe.Client{Variables: fake}
+
+		vars := []tfvar.Variable{
+			{Name: "region", Type: cty.String, Value: cty.StringVal("us-west-1")},
+		}
+
+		report, err := Push(context.Background(), client, "ws-1", vars, PushOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Change{{Key: "region", Action: ActionSkipped}}, report.Changes)
+		assert.Empty(t, fake.calls)
+	})
+
+	t.Run("deletes extraneous variables when asked", func(t *testing.T) {
+		fake := newFakeVariables(&tfe.Variable{
+			ID: "var-1", Key: "stale", Value: "old", Category: tfe.CategoryTerraform,
+		})
+		client := &tfe.Client{Variables: fake}
+
+		report, err := Push(context.Background(), client, "ws-1", nil, PushOptions{DeleteExtraneous: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Change{{Key: "stale", Action: ActionDeleted}}, report.Changes)
+		assert.Equal(t, []string{"delete:var-1"}, fake.calls)
+		assert.NotContains(t, fake.byID, "var-1")
+	})
+
+	t.Run("dry run reports actions without calling the API", func(t *testing.T) {
+		fake := newFakeVariables(&tfe.Variable{
+			ID: "var-1", Key: "stale", Value: "old", Category: tfe.CategoryTerraform,
+		})
+		client := &tfe.Client{Variables: fake}
+
+		vars := []tfvar.Variable{
+			{Name: "region", Type: cty.String, Value: cty.StringVal("us-west-1")},
+		}
+
+		report, err := Push(context.Background(), client, "ws-1", vars, PushOptions{DryRun: true, DeleteExtraneous: true})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []Change{
+			{Key: "region", Action: ActionCreated},
+			{Key: "stale", Action: ActionDeleted},
+		}, report.Changes)
+		assert.Empty(t, fake.calls)
+	})
+}
+
+func TestEncodeValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		v         tfvar.Variable
+		wantValue string
+		wantHCL   bool
+	}{
+		{
+			name:      "primitive string",
+			v:         tfvar.Variable{Type: cty.String, Value: cty.StringVal("us-west-1")},
+			wantValue: "us-west-1",
+			wantHCL:   false,
+		},
+		{
+			name:      "list",
+			v:         tfvar.Variable{Type: cty.List(cty.String), Value: cty.ListVal([]cty.Value{cty.StringVal("a")})},
+			wantValue: `["a"]`,
+			wantHCL:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, isHCL := encodeValue(tt.v)
+			assert.Equal(t, tt.wantValue, value)
+			assert.Equal(t, tt.wantHCL, isHCL)
+		})
+	}
+}
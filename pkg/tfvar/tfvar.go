@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -20,15 +21,37 @@ import (
 type Variable struct {
 	Name           string
 	Value          cty.Value
+	Type           cty.Type
 	Description    string
 	DescriptionSet bool
+	// Sensitive mirrors a `sensitive = true` attribute on the variable block.
+	// The pinned configs.Variable this package reads from predates
+	// Terraform 0.14's `sensitive` attribute, so Load reads it directly off
+	// the underlying HCL body itself rather than through configs.Variable;
+	// see newConfigParser.
+	Sensitive bool
+	// Validations holds the `validation { ... }` blocks declared on the
+	// variable, in declaration order. The pinned configs package only
+	// accepts `validation` blocks when the module opts in with a
+	// `terraform { experiments = [variable_validation] }` block, matching
+	// Terraform 0.12.27's own requirement for this still-experimental
+	// feature; modules that don't opt in simply have no Validations.
+	Validations []Validation
+	// ModulePath records the module call path this variable was declared
+	// under, e.g. []string{"network"} for a variable found while descending
+	// into a `module "network" { ... }` call via LoadRecursive. It is empty
+	// for variables declared directly in the module passed to Load.
+	ModulePath []string
 
 	parsingMode configs.VariableParsingMode
 }
 
 // Load extracts all input variables declared in the Terraform configurations located in dir.
 func Load(dir string) ([]Variable, error) {
-	parser := configs.NewParser(nil)
+	parser, sensitive, err := newConfigParser(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	modules, diag := parser.LoadConfigDir(dir)
 	if diag.HasErrors() {
@@ -38,11 +61,22 @@ func Load(dir string) ([]Variable, error) {
 	variables := make([]Variable, 0, len(modules.Variables))
 
 	for _, v := range modules.Variables {
+		validations := make([]Validation, 0, len(v.Validations))
+		for _, vv := range v.Validations {
+			validations = append(validations, Validation{
+				Condition:    vv.Condition,
+				ErrorMessage: vv.ErrorMessage,
+			})
+		}
+
 		variables = append(variables, Variable{
 			Name:           v.Name,
 			Value:          v.Default,
+			Type:           v.Type,
 			Description:    v.Description,
 			DescriptionSet: v.DescriptionSet,
+			Sensitive:      sensitive[v.Name],
+			Validations:    validations,
 
 			parsingMode: v.ParsingMode,
 		})
@@ -53,9 +87,16 @@ func Load(dir string) ([]Variable, error) {
 
 const varEnvPrefix = "TF_VAR_"
 
+// sensitivePlaceholder is written in place of a sensitive variable's real
+// value when maskSensitive is enabled.
+const sensitivePlaceholder = "<sensitive>"
+
 // WriteAsEnvVars outputs the given vars in environment variables format, e.g.
 //    export TF_VAR_region='ap-northeast-1'
-func WriteAsEnvVars(w io.Writer, vars []Variable, header string, enableDescriptions bool) error {
+// When maskSensitive is true, variables with Sensitive set are written with
+// sensitivePlaceholder instead of their real value, preceded by a
+// "# sensitive" comment.
+func WriteAsEnvVars(w io.Writer, vars []Variable, header string, enableDescriptions bool, maskSensitive bool) error {
 	var we error
 
 	if header != "" {
@@ -66,7 +107,12 @@ func WriteAsEnvVars(w io.Writer, vars []Variable, header string, enableDescripti
 	}
 
 	for _, v := range vars {
+		masked := v.Sensitive && maskSensitive
+
 		val := convertNull(v.Value)
+		if masked {
+			val = cty.StringVal(sensitivePlaceholder)
+		}
 
 		t := hclwrite.TokensForValue(val)
 		b := t.Bytes()
@@ -75,10 +121,15 @@ func WriteAsEnvVars(w io.Writer, vars []Variable, header string, enableDescripti
 
 		if we == nil {
 			var err error
-			if enableDescriptions {
-				_, err = fmt.Fprintf(w, "# %s\nexport %s%s='%s'\n", v.Description, varEnvPrefix, v.Name, string(b))
-			} else {
-				_, err = fmt.Fprintf(w, "export %s%s='%s'\n", varEnvPrefix, v.Name, string(b))
+			switch {
+			case enableDescriptions && masked:
+				_, err = fmt.Fprintf(w, "# %s\n# sensitive\nexport %s%s='%s'\n", v.Description, varEnvPrefix, v.LeafName(), string(b))
+			case enableDescriptions:
+				_, err = fmt.Fprintf(w, "# %s\nexport %s%s='%s'\n", v.Description, varEnvPrefix, v.LeafName(), string(b))
+			case masked:
+				_, err = fmt.Fprintf(w, "# sensitive\nexport %s%s='%s'\n", varEnvPrefix, v.LeafName(), string(b))
+			default:
+				_, err = fmt.Fprintf(w, "export %s%s='%s'\n", varEnvPrefix, v.LeafName(), string(b))
 			}
 			if err != nil {
 				we = errors.Wrap(err, "tfvar: unexpected writing export")
@@ -91,7 +142,11 @@ func WriteAsEnvVars(w io.Writer, vars []Variable, header string, enableDescripti
 
 // WriteAsTFVars outputs the given vars in Terraform's variable definitions format, e.g.
 //    region = "ap-northeast-1"
-func WriteAsTFVars(w io.Writer, vars []Variable, header string, enableDescriptions bool) error {
+// When maskSensitive is true, variables with Sensitive set are written with
+// sensitivePlaceholder instead of their real value. When enableDescriptions
+// is true, each variable's validation blocks are rendered as comments
+// alongside its description so the output documents the constraints.
+func WriteAsTFVars(w io.Writer, vars []Variable, header string, enableDescriptions bool, maskSensitive bool) error {
 	f := hclwrite.NewEmptyFile()
 	rootBody := f.Body()
 
@@ -100,7 +155,25 @@ func WriteAsTFVars(w io.Writer, vars []Variable, header string, enableDescriptio
 		rootBody.AppendUnstructuredTokens(headerTokens)
 	}
 
+	lastModulePath := ""
+
 	for _, v := range vars {
+		modulePath := strings.Join(v.ModulePath, ".")
+		if modulePath != lastModulePath {
+			if modulePath != "" {
+				header := fmt.Sprintf("## module: %s\n\n", modulePath)
+				rootBody.AppendUnstructuredTokens(makeCommentTokens(header))
+			}
+			lastModulePath = modulePath
+		}
+
+		masked := v.Sensitive && maskSensitive
+
+		val := v.Value
+		if masked {
+			val = cty.StringVal(sensitivePlaceholder)
+		}
+
 		if enableDescriptions {
 			commentText := ""
 			if v.Value.IsNull() {
@@ -108,9 +181,15 @@ func WriteAsTFVars(w io.Writer, vars []Variable, header string, enableDescriptio
 			} else {
 				commentText += "## OPTIONAL\n"
 			}
+			if masked {
+				commentText += "# sensitive\n"
+			}
 			if v.DescriptionSet {
 				commentText += "# " + v.Description + "\n"
 			}
+			for _, validation := range v.Validations {
+				commentText += "# validation: " + describeValidation(validation) + "\n"
+			}
 			if !v.Value.IsNull() {
 				commentText += "#"
 			}
@@ -118,10 +197,10 @@ func WriteAsTFVars(w io.Writer, vars []Variable, header string, enableDescriptio
 			appendTokens := makeNewlineTokens()
 
 			rootBody.AppendUnstructuredTokens(commentTokens)
-			rootBody.SetAttributeValue(v.Name, v.Value)
+			rootBody.SetAttributeValue(v.LeafName(), val)
 			rootBody.AppendUnstructuredTokens(appendTokens)
 		} else {
-			rootBody.SetAttributeValue(v.Name, v.Value)
+			rootBody.SetAttributeValue(v.LeafName(), val)
 		}
 	}
 
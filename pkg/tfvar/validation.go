@@ -0,0 +1,113 @@
+package tfvar
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// validationFuncs are the functions available to a validation condition,
+// e.g. `length(var.instance_name) > 0`. Terraform makes its full function
+// library available here, so Validate does too, via the same lang.Scope
+// Terraform itself uses to evaluate expressions.
+var validationFuncs = (&lang.Scope{}).Functions()
+
+// Validation represents a single `validation { ... }` block declared inside
+// a `variable` block, e.g.
+//    validation {
+//      condition     = var.instance_name != ""
+//      error_message = "instance_name must not be empty."
+//    }
+type Validation struct {
+	Condition    hcl.Expression
+	ErrorMessage string
+}
+
+// Validate evaluates every variable's Validations against its current Value
+// and returns an aggregated error combining every failing error_message, or
+// nil if every condition holds. Callers can use this to check a merged
+// value set, e.g. one produced by LoadValues, before shelling out to
+// terraform.
+func Validate(vars []Variable) error {
+	var result *multierror.Error
+
+	for _, v := range vars {
+		if len(v.Validations) == 0 {
+			continue
+		}
+
+		val := v.Value
+		if val == cty.NilVal {
+			val = cty.NullVal(v.Type)
+		}
+
+		ctx := &hcl.EvalContext{
+			Variables: map[string]cty.Value{
+				"var": cty.ObjectVal(map[string]cty.Value{v.Name: val}),
+			},
+			Functions: validationFuncs,
+		}
+
+		for _, validation := range v.Validations {
+			ok, diag := validation.Condition.Value(ctx)
+			if diag.HasErrors() {
+				result = multierror.Append(result, errors.Wrapf(diag, "tfvar: evaluating validation for %q", v.Name))
+				continue
+			}
+
+			if ok.False() {
+				result = multierror.Append(result, errors.Newf("tfvar: %s: %s", v.Name, validation.ErrorMessage))
+			}
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// describeValidation renders a Validation as a single-line comment
+// combining its condition's source text, when it can be recovered, and its
+// error message.
+func describeValidation(v Validation) string {
+	if cond := conditionSource(v.Condition); cond != "" {
+		return fmt.Sprintf("%s -- %s", cond, v.ErrorMessage)
+	}
+	return v.ErrorMessage
+}
+
+// conditionSource best-effort recovers the original source text of a
+// validation condition. expr's range is relative to the bytes
+// newConfigParser actually handed to the parser, which, for a file with a
+// stripped "sensitive" attribute, are not the on-disk bytes; it prefers
+// overlaySource's cached copy of those bytes and only falls back to
+// re-reading the file directly for content newConfigParser didn't stage
+// there. It returns "" if the source is unavailable.
+func conditionSource(expr hcl.Expression) string {
+	rng := expr.Range()
+
+	src, err := sourceBytes(rng.Filename)
+	if err != nil {
+		return ""
+	}
+
+	if rng.Start.Byte < 0 || rng.End.Byte > len(src) || rng.Start.Byte > rng.End.Byte {
+		return ""
+	}
+
+	return string(src[rng.Start.Byte:rng.End.Byte])
+}
+
+// sourceBytes returns the bytes a validation condition's range should be
+// sliced against: overlaySource's cached copy, if newConfigParser staged
+// one for this path, or the file read fresh from disk otherwise.
+func sourceBytes(filename string) ([]byte, error) {
+	if cached, ok := overlaySource.Load(filename); ok {
+		return cached.([]byte), nil
+	}
+
+	return ioutil.ReadFile(filename)
+}
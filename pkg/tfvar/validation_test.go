@@ -0,0 +1,125 @@
+package tfvar
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func loadValidated(t *testing.T, src string) []Variable {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(src), 0o644))
+
+	vars, err := Load(dir)
+	require.NoError(t, err)
+	return vars
+}
+
+const validatedVarTF = `
+terraform {
+  experiments = [variable_validation]
+}
+
+variable "instance_name" {
+  type = string
+
+  validation {
+    condition     = length(var.instance_name) > 0
+    error_message = "Instance name must not be empty."
+  }
+}
+`
+
+func TestValidate(t *testing.T) {
+	t.Run("passes when the condition holds", func(t *testing.T) {
+		vars := loadValidated(t, validatedVarTF)
+		require.Len(t, vars, 1)
+		vars[0].Value = cty.StringVal("my-instance")
+
+		assert.NoError(t, Validate(vars))
+	})
+
+	t.Run("fails when the condition doesn't hold", func(t *testing.T) {
+		vars := loadValidated(t, validatedVarTF)
+		require.Len(t, vars, 1)
+		vars[0].Value = cty.StringVal("")
+
+		err := Validate(vars)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Instance name must not be empty.")
+	})
+
+	t.Run("skips variables with no validations", func(t *testing.T) {
+		vars := []Variable{{Name: "region", Type: cty.String, Value: cty.StringVal("us-west-1")}}
+		assert.NoError(t, Validate(vars))
+	})
+}
+
+func TestDescribeValidation(t *testing.T) {
+	vars := loadValidated(t, validatedVarTF)
+	require.Len(t, vars, 1)
+	require.Len(t, vars[0].Validations, 1)
+
+	desc := describeValidation(vars[0].Validations[0])
+	assert.Equal(t, `length(var.instance_name) > 0 -- Instance name must not be empty.`, desc)
+}
+
+// TestDescribeValidationWithSensitiveAttribute covers a file that declares
+// both a `sensitive = true` variable and a validated one: stripping the
+// "sensitive" attribute before handing the file to the real parser shifts
+// every later byte offset, so describeValidation must recover the
+// condition's source text from the same stripped bytes the parser actually
+// saw rather than the original on-disk file, or it slices garbage out of
+// the wrong offsets.
+func TestDescribeValidationWithSensitiveAttribute(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+terraform {
+  experiments = [variable_validation]
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+  default   = "secret"
+}
+
+variable "instance_name" {
+  type    = string
+  default = "my-instance"
+
+  validation {
+    condition     = length(var.instance_name) > 0
+    error_message = "Instance name must not be empty."
+  }
+}
+`), 0o644))
+
+	vars, err := Load(dir)
+	require.NoError(t, err)
+
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+
+	require.Len(t, vars, 2)
+	require.Len(t, vars[0].Validations, 1)
+	assert.Equal(
+		t,
+		`length(var.instance_name) > 0 -- Instance name must not be empty.`,
+		describeValidation(vars[0].Validations[0]),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAsTFVars(&buf, vars, "", true, true))
+
+	_, diag := hclparse.NewParser().ParseHCL(buf.Bytes(), "out.tfvars")
+	assert.False(t, diag.HasErrors(), "WriteAsTFVars output should be valid HCL, got:\n%s\ndiagnostics: %s", buf.String(), diag)
+}
@@ -0,0 +1,357 @@
+package tfvar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+const (
+	defaultVarsFilename     = "terraform.tfvars"
+	defaultVarsFilenameJSON = defaultVarsFilename + ".json"
+)
+
+// LoadOption customizes the behaviour of LoadValues.
+type LoadOption func(*loadOptions)
+
+// valueSource produces the values one -var-file or -var source contributes,
+// given the variables already declared. label identifies the source in
+// error messages.
+type valueSource struct {
+	label  string
+	values func(vars []Variable) (map[string]cty.Value, error)
+}
+
+type loadOptions struct {
+	varFileSources []valueSource
+	varSources     []valueSource
+}
+
+// WithVarFile adds the variable definitions in path to the set of sources
+// LoadValues consults, in the order the option is given. It corresponds to
+// Terraform's repeatable `-var-file=...` flag.
+func WithVarFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.varFileSources = append(o.varFileSources, valueSource{
+			label: path,
+			values: func(vars []Variable) (map[string]cty.Value, error) {
+				return valuesFromTFVarsFile(path, vars)
+			},
+		})
+	}
+}
+
+// WithVarFileValues adds an already-parsed set of values, e.g. one produced
+// by cliparse.ParseVarFile, to the set of sources LoadValues consults, in
+// the order the option is given. label identifies the source (typically the
+// file path) in error messages. Unlike WithVarFile, the values are used
+// as-is instead of being parsed a second time; they still go through the
+// same precedence ordering and declared-type conversion as every other
+// source.
+func WithVarFileValues(label string, values map[string]cty.Value) LoadOption {
+	return func(o *loadOptions) {
+		o.varFileSources = append(o.varFileSources, valueSource{
+			label:  label,
+			values: func([]Variable) (map[string]cty.Value, error) { return values, nil },
+		})
+	}
+}
+
+// WithVar overrides a single variable with raw, in the form "name=value".
+// Options are applied in the order given and after every -var-file, just
+// like Terraform's repeatable `-var` flag.
+func WithVar(raw string) LoadOption {
+	return func(o *loadOptions) {
+		o.varSources = append(o.varSources, valueSource{
+			label: "-var",
+			values: func(vars []Variable) (map[string]cty.Value, error) {
+				index := make(map[string]int, len(vars))
+				for i, v := range vars {
+					index[v.Name] = i
+				}
+				name, val, err := parseVarArg(raw, vars, index)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]cty.Value{name: val}, nil
+			},
+		})
+	}
+}
+
+// WithVarValue overrides a single variable with an already-parsed value,
+// e.g. one produced by cliparse.ParseVarArg. Unlike WithVar, value is used
+// as-is instead of being parsed a second time; it still goes through the
+// same precedence ordering and declared-type conversion as every other
+// source.
+func WithVarValue(name string, value cty.Value) LoadOption {
+	return func(o *loadOptions) {
+		o.varSources = append(o.varSources, valueSource{
+			label:  "-var " + name,
+			values: func([]Variable) (map[string]cty.Value, error) { return map[string]cty.Value{name: value}, nil },
+		})
+	}
+}
+
+// LoadValues behaves like Load but additionally resolves the effective value
+// of every variable using the same source precedence as `terraform plan`:
+//
+//  1. TF_VAR_<name> environment variables
+//  2. terraform.tfvars / terraform.tfvars.json, if present
+//  3. *.auto.tfvars and *.auto.tfvars.json, in lexical order
+//  4. -var-file sources, in the order given
+//  5. -var overrides, in the order given
+//
+// Later sources take precedence over earlier ones, and any of them take
+// precedence over the variable's declared default.
+func LoadValues(dir string, opts ...LoadOption) ([]Variable, error) {
+	vars, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	index := make(map[string]int, len(vars))
+	for i, v := range vars {
+		index[v.Name] = i
+	}
+
+	apply := func(source string, values map[string]cty.Value) error {
+		for name, val := range values {
+			i, ok := index[name]
+			if !ok {
+				continue
+			}
+
+			converted, err := convert.Convert(val, vars[i].Type)
+			if err != nil {
+				return errors.Wrapf(err, "tfvar: value for variable %q from %s", name, source)
+			}
+
+			vars[i].Value = converted
+		}
+		return nil
+	}
+
+	envValues, err := valuesFromEnv(vars)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply("environment", envValues); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{defaultVarsFilename, defaultVarsFilenameJSON} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		values, err := valuesFromTFVarsFile(path, vars)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(path, values); err != nil {
+			return nil, err
+		}
+	}
+
+	autoFiles, err := autoVarFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range autoFiles {
+		values, err := valuesFromTFVarsFile(path, vars)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(path, values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, src := range o.varFileSources {
+		values, err := src.values(vars)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(src.label, values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, src := range o.varSources {
+		values, err := src.values(vars)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(src.label, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return vars, nil
+}
+
+// autoVarFiles returns the *.auto.tfvars and *.auto.tfvars.json files in dir,
+// sorted lexically by name.
+func autoVarFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tfvar: reading %s", dir)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json") {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+
+	return paths, nil
+}
+
+// valuesFromEnv extracts TF_VAR_<name> environment variables for the
+// variables declared in vars, parsing each raw string using the variable's
+// own parsing mode.
+func valuesFromEnv(vars []Variable) (map[string]cty.Value, error) {
+	byName := make(map[string]Variable, len(vars))
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+
+	values := make(map[string]cty.Value)
+
+	for _, raw := range os.Environ() {
+		if !strings.HasPrefix(raw, varEnvPrefix) {
+			continue
+		}
+		raw = raw[len(varEnvPrefix):]
+
+		eq := strings.IndexByte(raw, '=')
+		if eq == -1 {
+			continue
+		}
+
+		name, rawVal := raw[:eq], raw[eq+1:]
+
+		v, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		val, err := v.ParseCLIValue(rawVal)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: environment variable %s%s", varEnvPrefix, name)
+		}
+
+		values[name] = val
+	}
+
+	return values, nil
+}
+
+// valuesFromTFVarsFile reads a .tfvars or .tfvars.json file, parsing HCL
+// syntax with hclparse and JSON syntax via the same parser's JSON support,
+// and returns only the values for variables declared in vars.
+func valuesFromTFVarsFile(path string, vars []Variable) (map[string]cty.Value, error) {
+	parser := hclparse.NewParser()
+
+	var (
+		f    *hcl.File
+		diag hcl.Diagnostics
+	)
+	if strings.HasSuffix(path, ".json") {
+		f, diag = parser.ParseJSONFile(path)
+	} else {
+		f, diag = parser.ParseHCLFile(path)
+	}
+	if diag.HasErrors() {
+		return nil, errors.Wrapf(diag, "tfvar: parsing %s", path)
+	}
+
+	attrs, diag := f.Body.JustAttributes()
+	if diag.HasErrors() {
+		return nil, errors.Wrapf(diag, "tfvar: reading attributes from %s", path)
+	}
+
+	byName := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		byName[v.Name] = true
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		if !byName[name] {
+			continue
+		}
+
+		val, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() {
+			return nil, errors.Wrapf(diag, "tfvar: evaluating %s in %s", name, path)
+		}
+
+		values[name] = val
+	}
+
+	return values, nil
+}
+
+// parseVarArg splits a "-var" argument of the form "name=value" and parses
+// value against the declared variable, so that e.g. an object-typed
+// variable can be given as HCL (`-var 'tags={env="dev"}'`) while a
+// string-typed one is taken literally.
+func parseVarArg(raw string, vars []Variable, index map[string]int) (string, cty.Value, error) {
+	eq := strings.IndexByte(raw, '=')
+	if eq == -1 {
+		return "", cty.NilVal, errors.Newf("tfvar: invalid -var %q, expected NAME=VALUE", raw)
+	}
+
+	name, rawVal := raw[:eq], raw[eq+1:]
+
+	i, ok := index[name]
+	if !ok {
+		return "", cty.NilVal, errors.Newf("tfvar: -var refers to undeclared variable %q", name)
+	}
+
+	val, err := vars[i].ParseCLIValue(rawVal)
+	if err != nil {
+		return "", cty.NilVal, errors.Wrapf(err, "tfvar: -var %s", name)
+	}
+
+	return name, val, nil
+}
+
+// ParseCLIValue parses raw, a string given on the command line (e.g. a
+// "-var" argument) or read from a TF_VAR_<name> environment variable, into
+// a value of v's declared type. It uses v's parsing mode to decide whether
+// raw is taken as a literal string or parsed as an HCL expression -- the
+// same decision Terraform itself makes for a variable of v's declared
+// type -- so this is the one place that decision is made; callers that
+// need to make it before v's full value is known, such as cliparse for an
+// undeclared variable, are on their own.
+func (v Variable) ParseCLIValue(raw string) (cty.Value, error) {
+	val, diag := v.parsingMode.Parse(v.Name, raw)
+	if diag.HasErrors() {
+		return cty.NilVal, errors.Wrap(diag, "tfvar: parsing value")
+	}
+
+	converted, err := convert.Convert(val, v.Type)
+	if err != nil {
+		return cty.NilVal, errors.Wrap(err, "tfvar: value does not match declared type")
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,130 @@
+package tfvar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func setupModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func valueOf(t *testing.T, vars []Variable, name string) string {
+	t.Helper()
+	for _, v := range vars {
+		if v.Name == name {
+			return v.Value.AsString()
+		}
+	}
+	t.Fatalf("no variable named %q", name)
+	return ""
+}
+
+func TestLoadValuesPrecedence(t *testing.T) {
+	mainTF := `
+variable "region" {
+  type    = string
+  default = "default-region"
+}
+`
+
+	t.Run("falls back to the declared default", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{"main.tf": mainTF})
+
+		vars, err := LoadValues(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "default-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("environment variable overrides the default", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{"main.tf": mainTF})
+
+		require.NoError(t, os.Setenv("TF_VAR_region", "env-region"))
+		defer os.Unsetenv("TF_VAR_region")
+
+		vars, err := LoadValues(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "env-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("terraform.tfvars overrides the environment", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{
+			"main.tf":          mainTF,
+			"terraform.tfvars": `region = "tfvars-region"`,
+		})
+
+		require.NoError(t, os.Setenv("TF_VAR_region", "env-region"))
+		defer os.Unsetenv("TF_VAR_region")
+
+		vars, err := LoadValues(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "tfvars-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("auto.tfvars files apply in lexical order after terraform.tfvars", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{
+			"main.tf":          mainTF,
+			"terraform.tfvars": `region = "tfvars-region"`,
+			"a.auto.tfvars":    `region = "a-region"`,
+			"z.auto.tfvars":    `region = "z-region"`,
+		})
+
+		vars, err := LoadValues(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "z-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("-var-file overrides auto.tfvars", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{
+			"main.tf":       mainTF,
+			"a.auto.tfvars": `region = "a-region"`,
+		})
+
+		varFile := filepath.Join(dir, "extra.tfvars")
+		require.NoError(t, os.WriteFile(varFile, []byte(`region = "varfile-region"`), 0o644))
+
+		vars, err := LoadValues(dir, WithVarFile(varFile))
+		require.NoError(t, err)
+		assert.Equal(t, "varfile-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("-var overrides everything", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{
+			"main.tf":          mainTF,
+			"terraform.tfvars": `region = "tfvars-region"`,
+		})
+
+		vars, err := LoadValues(dir, WithVar("region=cli-region"))
+		require.NoError(t, err)
+		assert.Equal(t, "cli-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("WithVarValue and WithVarFileValues use already-parsed values", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{"main.tf": mainTF})
+
+		vars, err := LoadValues(dir,
+			WithVarFileValues("fake-file", map[string]cty.Value{"region": cty.StringVal("parsed-file-region")}),
+			WithVarValue("region", cty.StringVal("parsed-var-region")),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "parsed-var-region", valueOf(t, vars, "region"))
+	})
+
+	t.Run("-var for an undeclared variable is an error", func(t *testing.T) {
+		dir := setupModule(t, map[string]string{"main.tf": mainTF})
+
+		_, err := LoadValues(dir, WithVar("nope=1"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,189 @@
+package tfvar
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// WriteAsTFVarsJSON outputs the given vars as a Terraform JSON variable
+// definitions file (".tfvars.json"), e.g.
+//    {
+//      "region": "ap-northeast-1"
+//    }
+// Each value is marshaled with ctyjson.Marshal against the variable's
+// declared type (or, for a variable with no declared type, the value's own
+// type), so objects, lists, maps, and null all round-trip the way
+// Terraform itself would write them. header and enableDescriptions are
+// accepted for parity with WriteAsEnvVars and WriteAsTFVars but have no
+// effect here: strict JSON has no comment syntax, and Terraform treats any
+// extra top-level key in a .tfvars.json file as an undeclared variable.
+func WriteAsTFVarsJSON(w io.Writer, vars []Variable, header string, enableDescriptions bool) error {
+	raw, err := json.MarshalIndent(orderedVars(vars), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "tfvar: failed to write as tfvars json")
+	}
+
+	raw = append(raw, '\n')
+
+	_, err = w.Write(raw)
+	return errors.Wrap(err, "tfvar: failed to write as tfvars json")
+}
+
+// orderedVars marshals a []Variable as a JSON object keyed by name, keeping
+// the given slice order rather than the alphabetical order that marshaling a
+// plain map would produce.
+type orderedVars []Variable
+
+func (vs orderedVars) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, v := range vs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(v.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: marshaling name of %s", v.Name)
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		t := v.Type
+		if t == cty.NilType {
+			t = v.Value.Type()
+		}
+
+		val := v.Value
+		if val == cty.NilVal {
+			val = cty.NullVal(t)
+		}
+
+		// An untyped variable's value is marshaled against its own type;
+		// marshaling against cty.DynamicPseudoType would wrap it in a
+		// {"value": ..., "type": ...} object.
+		if t == cty.DynamicPseudoType {
+			t = val.Type()
+		}
+
+		raw, err := ctyjson.Marshal(val, t)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tfvar: marshaling %s as JSON", v.Name)
+		}
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// WriteAsJSONSchema outputs a JSON Schema draft-07 document describing the
+// given vars, suitable for driving form generators, validators, or docs
+// sites off of a module's declared inputs. Each variable's cty.Type is
+// converted to its JSON Schema equivalent, its description (when set) fills
+// the schema property's "description", and variables with no default
+// (Value is cty.NilVal or null) are listed under "required".
+func WriteAsJSONSchema(w io.Writer, vars []Variable) error {
+	properties := make(map[string]interface{}, len(vars))
+
+	var required []string
+
+	for _, v := range vars {
+		t := v.Type
+		if t == cty.NilType {
+			t = cty.DynamicPseudoType
+		}
+
+		prop := typeToJSONSchema(t)
+		if v.DescriptionSet {
+			prop["description"] = v.Description
+		}
+		properties[v.Name] = prop
+
+		if v.Value == cty.NilVal || v.Value.IsNull() {
+			required = append(required, v.Name)
+		}
+	}
+
+	sort.Strings(required)
+
+	schema := map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	raw, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "tfvar: failed to write as json schema")
+	}
+
+	raw = append(raw, '\n')
+
+	_, err = w.Write(raw)
+	return errors.Wrap(err, "tfvar: failed to write as json schema")
+}
+
+// typeToJSONSchema converts a cty.Type to its JSON Schema equivalent.
+func typeToJSONSchema(t cty.Type) map[string]interface{} {
+	switch {
+	case t == cty.DynamicPseudoType:
+		return map[string]interface{}{
+			"oneOf": []map[string]interface{}{
+				{"type": "string"},
+				{"type": "number"},
+				{"type": "boolean"},
+				{"type": "array"},
+				{"type": "object"},
+			},
+		}
+	case t == cty.String:
+		return map[string]interface{}{"type": "string"}
+	case t == cty.Number:
+		return map[string]interface{}{"type": "number"}
+	case t == cty.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.IsListType(), t.IsSetType():
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeToJSONSchema(t.ElementType()),
+		}
+	case t.IsTupleType():
+		elemTypes := t.TupleElementTypes()
+		items := make([]map[string]interface{}, len(elemTypes))
+		for i, et := range elemTypes {
+			items[i] = typeToJSONSchema(et)
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		}
+	case t.IsMapType():
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeToJSONSchema(t.ElementType()),
+		}
+	case t.IsObjectType():
+		props := make(map[string]interface{})
+		for name, at := range t.AttributeTypes() {
+			props[name] = typeToJSONSchema(at)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,104 @@
+package tfvar
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestWriteAsTFVarsJSON(t *testing.T) {
+	vars := []Variable{
+		{Name: "instance_name", Type: cty.String, Value: cty.StringVal("my-instance")},
+		{Name: "region", Type: cty.String},
+		{Name: "availability_zone_names", Type: cty.List(cty.String), Value: cty.ListVal([]cty.Value{cty.StringVal("us-west-1a")})},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAsTFVarsJSON(&buf, vars, "", false))
+
+	expected := `{
+  "instance_name": "my-instance",
+  "region": null,
+  "availability_zone_names": ["us-west-1a"]
+}
+`
+	assert.JSONEq(t, expected, buf.String())
+
+	// orderedVars keeps declaration order rather than sorting keys, which
+	// assert.JSONEq can't see since it compares parsed values.
+	assert.Equal(t, `{"instance_name":"my-instance","region":null,"availability_zone_names":["us-west-1a"]}`, compactJSON(t, buf.String()))
+}
+
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, []byte(s)))
+	return buf.String()
+}
+
+func TestWriteAsJSONSchema(t *testing.T) {
+	vars := []Variable{
+		{Name: "region", Type: cty.String, DescriptionSet: true, Description: "AWS region"},
+		{Name: "instance_name", Type: cty.String, Value: cty.StringVal("my-instance")},
+		{Name: "tags", Type: cty.Map(cty.String), Value: cty.MapValEmpty(cty.String)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAsJSONSchema(&buf, vars))
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+
+	assert.Equal(t, []interface{}{"region"}, schema["required"])
+
+	properties := schema["properties"].(map[string]interface{})
+
+	region := properties["region"].(map[string]interface{})
+	assert.Equal(t, "string", region["type"])
+	assert.Equal(t, "AWS region", region["description"])
+
+	tags := properties["tags"].(map[string]interface{})
+	assert.Equal(t, "object", tags["type"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, tags["additionalProperties"])
+}
+
+func TestTypeToJSONSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		t    cty.Type
+		want map[string]interface{}
+	}{
+		{"string", cty.String, map[string]interface{}{"type": "string"}},
+		{"number", cty.Number, map[string]interface{}{"type": "number"}},
+		{"bool", cty.Bool, map[string]interface{}{"type": "boolean"}},
+		{
+			"list",
+			cty.List(cty.String),
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		{
+			"map",
+			cty.Map(cty.Number),
+			map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "number"}},
+		},
+		{
+			"object",
+			cty.Object(map[string]cty.Type{"name": cty.String}),
+			map[string]interface{}{
+				"type":                 "object",
+				"properties":           map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+				"additionalProperties": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, typeToJSONSchema(tt.t))
+		})
+	}
+}